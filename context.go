@@ -0,0 +1,115 @@
+package lambdaproxy
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// defaultInvocationTimeout is the deadline lambdaproxy assumes when
+// AWS_LAMBDA_FUNCTION_TIMEOUT isn't set (e.g. outside a real Lambda
+// invocation, such as under ServeHTTP for local development). It does not
+// claim to mirror any particular function's configured timeout - set
+// AWS_LAMBDA_FUNCTION_TIMEOUT (seconds) or call ctx.WithTimeout to get an
+// accurate one.
+const defaultInvocationTimeout = 30 * time.Second
+
+// invocationDeadline derives a wall-clock deadline for the invocation from
+// AWS_LAMBDA_FUNCTION_TIMEOUT (seconds), the env var Lambda's Go runtime
+// sets to the function's configured timeout, measured from started.
+// apex.Context itself is a plain data struct with no deadline or
+// remaining-time accessor, so there's nothing to read off it directly.
+func invocationDeadline(started time.Time) time.Time {
+	timeout := defaultInvocationTimeout
+	if s := os.Getenv("AWS_LAMBDA_FUNCTION_TIMEOUT"); s != "" {
+		if secs, err := strconv.Atoi(s); err == nil && secs > 0 {
+			timeout = time.Duration(secs) * time.Second
+		}
+	}
+	return started.Add(timeout)
+}
+
+// deadlineContext is a context.Context whose Done/Err fire either when the
+// Lambda invocation's own deadline elapses or when WithTimeout sets a
+// tighter one. Unlike chaining context.WithTimeout calls, a single timer is
+// reused (Reset, not re-armed) across repeated WithTimeout calls within one
+// invocation, mirroring how net.Conn.SetDeadline reuses one timer rather
+// than leaking a goroutine per call.
+type deadlineContext struct {
+	mu       sync.Mutex
+	deadline time.Time
+	timer    *time.Timer
+	done     chan struct{}
+	err      error
+	closed   bool
+}
+
+func newDeadlineContext(deadline time.Time) *deadlineContext {
+	c := &deadlineContext{deadline: deadline, done: make(chan struct{})}
+	if d := time.Until(deadline); d > 0 {
+		c.timer = time.AfterFunc(d, func() { c.fire(context.DeadlineExceeded) })
+	} else {
+		c.fire(context.DeadlineExceeded)
+	}
+	return c
+}
+
+func (c *deadlineContext) fire(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return
+	}
+	c.closed = true
+	c.err = err
+	close(c.done)
+}
+
+// setTimeout resets the shared timer to fire in d, unless it has already
+// fired. It never pushes the deadline later than it already is.
+func (c *deadlineContext) setTimeout(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return
+	}
+	deadline := time.Now().Add(d)
+	if !deadline.Before(c.deadline) {
+		return
+	}
+	c.deadline = deadline
+	if c.timer == nil {
+		c.timer = time.AfterFunc(d, func() { c.fire(context.DeadlineExceeded) })
+		return
+	}
+	c.timer.Reset(d)
+}
+
+func (c *deadlineContext) Deadline() (time.Time, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.deadline, true
+}
+
+func (c *deadlineContext) Done() <-chan struct{} { return c.done }
+
+func (c *deadlineContext) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// invocationContext is the context.Context handed out by Context.Context().
+// Done/Err/Deadline delegate to the invocation's shared deadlineContext;
+// Value delegates to the WithValue chain built up during the invocation.
+type invocationContext struct {
+	deadline *deadlineContext
+	values   context.Context
+}
+
+func (c invocationContext) Deadline() (time.Time, bool)       { return c.deadline.Deadline() }
+func (c invocationContext) Done() <-chan struct{}             { return c.deadline.Done() }
+func (c invocationContext) Err() error                        { return c.deadline.Err() }
+func (c invocationContext) Value(key interface{}) interface{} { return c.values.Value(key) }
@@ -0,0 +1,24 @@
+package lambdaproxy
+
+import (
+	"testing"
+	"time"
+)
+
+func TestContextWithTimeoutNeverLoosensDeadline(t *testing.T) {
+	ctx := newContext(&Request{}, nil)
+
+	before, _ := ctx.Context().Deadline()
+
+	ctx.WithTimeout(time.Hour)
+	after, _ := ctx.Context().Deadline()
+	if !after.Equal(before) {
+		t.Fatalf("expected a looser timeout to be ignored, deadline moved from %v to %v", before, after)
+	}
+
+	ctx.WithTimeout(time.Millisecond)
+	tighter, _ := ctx.Context().Deadline()
+	if !tighter.Before(before) {
+		t.Fatalf("expected a tighter timeout to move the deadline earlier, got %v (was %v)", tighter, before)
+	}
+}
@@ -42,8 +42,8 @@ func Handle(h ...HandlerFunc) {
 			Body:       "",
 			Headers:    EmptyHeaders,
 		}
-		hCtx := &Context{Request: req}
-		return execHandlerFuncs(resp, hCtx, h...)
+		hCtx := newContext(req, nil)
+		return runChain(resp, hCtx, composeChain(h...))
 	})
 }
 
@@ -51,6 +51,8 @@ type Route struct {
 	HTTPMethod   string
 	ResourcePath string
 	HandlerFuncs []HandlerFunc
+
+	middleware []MiddlewareFunc
 }
 
 func routeKey(method, path string) string {
@@ -58,19 +60,104 @@ func routeKey(method, path string) string {
 }
 
 type router struct {
-	routes          map[string]*Route
-	notFoundHandler HandlerFunc
+	routes           map[string]*Route
+	trees            map[string]*pathNode
+	notFoundHandler  HandlerFunc
+	prefix           string
+	middleware       []MiddlewareFunc
+	binaryMediaTypes []string
+}
+
+// SetBinaryMediaTypes configures the content types (exact, "image/*"
+// wildcard, or "*/*") that Context.Blob treats as binary, i.e. sets
+// isBase64Encoded for. This mirrors API Gateway's own binaryMediaTypes
+// configuration and should match it.
+func (r *router) SetBinaryMediaTypes(types ...string) *router {
+	r.binaryMediaTypes = types
+	return r
+}
+
+// Use appends middleware that will wrap every route registered on r from
+// this point on. Middleware registered via Use runs outermost-first, in the
+// order it was added.
+func (r *router) Use(mw ...MiddlewareFunc) *router {
+	r.middleware = append(r.middleware, mw...)
+	return r
+}
+
+// Group returns a child router that shares the parent's route table but
+// prefixes every path registered on it with prefix and stacks mw on top of
+// the parent's own middleware.
+func (r *router) Group(prefix string, mw ...MiddlewareFunc) *router {
+	middleware := make([]MiddlewareFunc, 0, len(r.middleware)+len(mw))
+	middleware = append(middleware, r.middleware...)
+	middleware = append(middleware, mw...)
+	return &router{
+		routes:           r.routes,
+		trees:            r.trees,
+		notFoundHandler:  r.notFoundHandler,
+		prefix:           r.prefix + prefix,
+		middleware:       middleware,
+		binaryMediaTypes: r.binaryMediaTypes,
+	}
 }
 
 func (r *router) Add(method, resourcePath string, h ...HandlerFunc) *router {
-	r.routes[routeKey(method, resourcePath)] = &Route{
+	path := r.prefix + resourcePath
+	route := &Route{
 		HTTPMethod:   method,
-		ResourcePath: resourcePath,
+		ResourcePath: path,
 		HandlerFuncs: h,
+		middleware:   append([]MiddlewareFunc{}, r.middleware...),
 	}
+	r.routes[routeKey(method, path)] = route
+
+	methodKey := strings.ToLower(strings.TrimSpace(method))
+	if r.trees[methodKey] == nil {
+		r.trees[methodKey] = newPathNode()
+	}
+	r.trees[methodKey].insert(splitPath(path), route)
+
 	return r
 }
 
+// Match resolves method and path against the routes registered with
+// :param/*wildcard patterns (see Add/GET/etc.), independently of API
+// Gateway's own Resource-based matching. It's what Serve falls back to when
+// req.Resource doesn't hit a literal route, and what ServeHTTP uses to
+// drive the router directly for local development.
+func (r *router) Match(method, path string) (*Route, map[string]string, bool) {
+	root, ok := r.trees[strings.ToLower(strings.TrimSpace(method))]
+	if !ok {
+		return nil, nil, false
+	}
+	rt, captured, ok := root.match(splitPath(path))
+	if !ok {
+		return nil, nil, false
+	}
+	return rt, namedParams(rt, captured), true
+}
+
+// namedParams pairs captured, the :param/*wildcard values a trie walk
+// captured in path order, with the names declared in route's own
+// ResourcePath - the only place those names are unambiguous, since two
+// routes can share a trie position under different names (see pathNode).
+func namedParams(route *Route, captured []string) map[string]string {
+	params := make(map[string]string, len(captured))
+	i := 0
+	for _, seg := range splitPath(route.ResourcePath) {
+		switch {
+		case strings.HasPrefix(seg, ":"):
+			params[seg[1:]] = captured[i]
+			i++
+		case strings.HasPrefix(seg, "*"):
+			params[seg[1:]] = captured[i]
+			i++
+		}
+	}
+	return params
+}
+
 func (r *router) GET(resourcePath string, h ...HandlerFunc) *router {
 	return r.Add("get", resourcePath, h...)
 }
@@ -114,42 +201,103 @@ func (r *router) Serve() {
 			Body:       "",
 			Headers:    EmptyHeaders,
 		}
-		hCtx := &Context{Request: req}
-		rt, ok := r.routes[routeKey(req.HTTPMethod, req.Resource)]
-		if !ok || len(rt.HandlerFuncs) == 0 {
-			return execHandlerFuncs(resp, hCtx, r.notFoundHandler)
-		}
-		return execHandlerFuncs(resp, hCtx, rt.HandlerFuncs...)
+		hCtx := newContext(req, r.binaryMediaTypes)
+		return runChain(resp, hCtx, r.handlerFor(req))
 	})
 }
 
-func execHandlerFuncs(resp *response, hctx *Context, h ...HandlerFunc) (interface{}, error) {
-	for _, hf := range h {
-		err := hf.Handle(hctx)
-		if err != nil {
-			if he, ok := err.(*HTTPError); ok {
-				return &response{
-					StatusCode: he.Code,
-					Body:       he.Message,
-					Headers:    EmptyHeaders,
-				}, nil
-			}
+// handlerFor resolves req to the fully composed, middleware-wrapped handler
+// it should run: a literal Resource-based lookup first, falling back to
+// Match's :param/*wildcard path matching (merging any extracted params into
+// req.PathParameters), and finally the not-found handler. Used by both
+// Serve and ServeHTTP so the two entry points can't drift apart.
+func (r *router) handlerFor(req *Request) HandlerFunc {
+	rt, ok := r.routes[routeKey(req.HTTPMethod, req.Resource)]
+	if !ok {
+		if mrt, params, matched := r.Match(req.HTTPMethod, req.Path); matched {
+			rt, ok = mrt, true
+			mergePathParameters(req, params)
+		}
+	}
+	if !ok || len(rt.HandlerFuncs) == 0 {
+		return wrapMiddleware(toResponseHandler(r.notFoundHandler), r.middleware)
+	}
+	return wrapMiddleware(toResponseHandler(composeChain(rt.HandlerFuncs...)), rt.middleware)
+}
 
-			log.Printf("[ERROR] lambdaProxy.Handle: error processing function handler: %s", err)
-			return &response{
-				StatusCode: http.StatusInternalServerError,
-				Body:       http.StatusText(http.StatusInternalServerError),
-				Headers:    EmptyHeaders,
-			}, nil
+// toResponseHandler converts any error h returns into ctx.response (via
+// errorResponse/buildErrorResponse) before it can reach the surrounding
+// middleware stack, and reports success (nil) instead. Without this,
+// middleware that only post-processes ctx.response on success - CORS
+// appending its headers, for instance - would never see error responses at
+// all, since a plain `return someErr` from a handler used to bypass
+// ctx.response entirely and get converted only once runChain unwound past
+// every middleware.
+func toResponseHandler(h HandlerFunc) HandlerFunc {
+	return func(ctx *Context) error {
+		if err := h(ctx); err != nil {
+			ctx.response = buildErrorResponse(err)
 		}
+		return nil
+	}
+}
 
-		if hctx.response != nil {
-			resp = hctx.response
+// composeChain folds a list of handlers into the single HandlerFunc a route
+// resolves to, running each in turn and stopping at the first error - the
+// same semantics the old flat for-loop had.
+func composeChain(h ...HandlerFunc) HandlerFunc {
+	return func(ctx *Context) error {
+		for _, hf := range h {
+			if err := hf(ctx); err != nil {
+				return err
+			}
 		}
+		return nil
+	}
+}
+
+// wrapMiddleware wraps h with mw so that mw[0] runs outermost and h runs
+// innermost, i.e. mw[0] -> mw[1] -> ... -> h.
+func wrapMiddleware(h HandlerFunc, mw []MiddlewareFunc) HandlerFunc {
+	for i := len(mw) - 1; i >= 0; i-- {
+		h = mw[i](h)
+	}
+	return h
+}
+
+// runChain invokes the fully composed handler chain h and turns its result
+// into the response shape Lambda's proxy integration expects. h is expected
+// to have already converted its own errors into ctx.response (see
+// toResponseHandler); the error branch here covers errors a middleware
+// returns itself and handler chains that don't go through that, such as
+// Handle's (there's no middleware to worry about composing with there).
+func runChain(resp *response, hctx *Context, h HandlerFunc) (interface{}, error) {
+	if err := h(hctx); err != nil {
+		return buildErrorResponse(err), nil
+	}
+
+	if hctx.response != nil {
+		resp = hctx.response
 	}
 	return resp, nil
 }
 
+// buildErrorResponse renders err as the response lambdaproxy sends back:
+// the stable JSON envelope for an *HTTPError, or a logged, opaque 500 for
+// anything else.
+func buildErrorResponse(err error) *response {
+	if he, ok := err.(*HTTPError); ok {
+		return errorResponse(he)
+	}
+
+	log.Printf("[ERROR] lambdaProxy.Handle: error processing function handler: %s", err)
+	return &response{
+		StatusCode: http.StatusInternalServerError,
+		Body:       http.StatusText(http.StatusInternalServerError),
+		Headers:    EmptyHeaders,
+	}
+}
+
 func notFoundHandler(c *Context) error {
 	b, err := json.Marshal(c)
 	if err != nil {
@@ -161,5 +309,21 @@ func notFoundHandler(c *Context) error {
 }
 
 func NewRouter() *router {
-	return &router{routes: make(map[string]*Route), notFoundHandler: notFoundHandler}
+	return &router{
+		routes:          make(map[string]*Route),
+		trees:           make(map[string]*pathNode),
+		notFoundHandler: notFoundHandler,
+	}
+}
+
+// mergePathParameters merges params extracted by router.Match into req's
+// existing PathParameters (set by API Gateway, if any), so ctx.Param keeps
+// working regardless of which matcher resolved the route.
+func mergePathParameters(req *Request, params map[string]string) {
+	if req.PathParameters == nil {
+		req.PathParameters = make(map[string]string, len(params))
+	}
+	for k, v := range params {
+		req.PathParameters[k] = v
+	}
 }
@@ -1,9 +1,15 @@
 package lambdaproxy
 
 import (
+	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/imdario/mergo"
 	"log"
@@ -15,9 +21,10 @@ func init() {
 }
 
 type response struct {
-	StatusCode int               `json:"statusCode"`
-	Headers    map[string]string `json:"headers"`
-	Body       string            `json:"body"`
+	StatusCode      int               `json:"statusCode"`
+	Headers         map[string]string `json:"headers"`
+	Body            string            `json:"body"`
+	IsBase64Encoded bool              `json:"isBase64Encoded"`
 }
 
 type Request struct {
@@ -30,6 +37,7 @@ type Request struct {
 	PathParameters        map[string]string `json:"pathParameters"`
 	StageVariables        map[string]string `json:"stageVariables"`
 	Body                  string            `json:"body"`
+	IsBase64Encoded       bool              `json:"isBase64Encoded"`
 }
 
 type RequestContext struct {
@@ -57,32 +65,140 @@ type Identity struct {
 	AccountID                     string `json:"accountId"`
 }
 
+// HTTPError is the error type handlers return to fail a request. Status is
+// the HTTP status code to respond with; Code is a stable, machine-readable
+// symbol (e.g. "validation_failed") clients can switch on without parsing
+// Message. Detail carries an optional structured payload, e.g. per-field
+// validation errors.
 type HTTPError struct {
-	Code    int    `json:"code"`
-	Message string `json:"message"`
+	Status    int             `json:"-"`
+	Code      string          `json:"code"`
+	Message   string          `json:"message"`
+	Detail    json.RawMessage `json:"detail,omitempty"`
+	Retryable bool            `json:"retryable,omitempty"`
 }
 
 func (he *HTTPError) Error() string {
-	return fmt.Sprintf("code=%d, message=%s", he.Code, he.Message)
+	return fmt.Sprintf("status=%d, code=%s, message=%s", he.Status, he.Code, he.Message)
 }
 
-func NewHTTPError(code int, message string) *HTTPError {
-	he := &HTTPError{Code: code, Message: http.StatusText(code)}
+// NewHTTPError builds an HTTPError for status with the given symbolic code.
+// message defaults to the standard HTTP status text when empty.
+func NewHTTPError(status int, code, message string) *HTTPError {
+	he := &HTTPError{Status: status, Code: code, Message: http.StatusText(status)}
 	if message != "" {
 		he.Message = message
 	}
 	return he
 }
 
+// withDetail marshals detail into he.Detail, ignoring marshal failures since
+// detail is best-effort context for the client.
+func (he *HTTPError) withDetail(detail interface{}) *HTTPError {
+	if detail == nil {
+		return he
+	}
+	if b, err := json.Marshal(detail); err == nil {
+		he.Detail = b
+	}
+	return he
+}
+
+// NewValidationError reports a 422 with code "validation_failed", typically
+// carrying per-field validation failures as detail.
+func NewValidationError(detail interface{}) *HTTPError {
+	return NewHTTPError(http.StatusUnprocessableEntity, "validation_failed", "").withDetail(detail)
+}
+
+// NewConflictError reports a 409 with code "conflict".
+func NewConflictError(detail interface{}) *HTTPError {
+	return NewHTTPError(http.StatusConflict, "conflict", "").withDetail(detail)
+}
+
+// NewNotFoundError reports a 404 with code "not_found".
+func NewNotFoundError(detail interface{}) *HTTPError {
+	return NewHTTPError(http.StatusNotFound, "not_found", "").withDetail(detail)
+}
+
+// errorResponse renders he as the stable JSON error envelope
+// {"code":"...","message":"...","detail":{...}}.
+func errorResponse(he *HTTPError) *response {
+	body, err := json.Marshal(he)
+	if err != nil {
+		log.Printf("[ERROR] lambdaproxy: unable to marshal http error: %s", err)
+		body = []byte(fmt.Sprintf(`{"code":"internal_error","message":%q}`, he.Message))
+	}
+	return &response{
+		StatusCode: he.Status,
+		Headers:    map[string]string{"Content-Type": "application/json"},
+		Body:       string(body),
+	}
+}
+
 var EmptyHeaders = map[string]string{}
 
 type Context struct {
 	Request  *Request  `json:"request"`
 	response *response `json:"response"`
+
+	requestID        string
+	binaryMediaTypes []string
+
+	deadline *deadlineContext
+	values   context.Context
+}
+
+// Context returns the request-scoped context.Context for this invocation.
+// Its deadline tracks the Lambda's own deadline (see WithTimeout) and it
+// carries whatever values middleware or handlers attached via WithValue.
+// Pass it to http.NewRequestWithContext, AWS SDK calls, database/sql
+// queries, etc. so they're cancelled when the Lambda is about to time out.
+func (ctx *Context) Context() context.Context {
+	return invocationContext{deadline: ctx.deadline, values: ctx.values}
+}
+
+// WithValue attaches k/v to the invocation's context.Context for downstream
+// middleware and handlers to read back via Context().Value(k).
+func (ctx *Context) WithValue(k, v interface{}) context.Context {
+	ctx.values = context.WithValue(ctx.values, k, v)
+	return ctx.Context()
+}
+
+// WithTimeout tightens the invocation's context.Context to be done after d,
+// never later than the Lambda's own deadline. It's safe to call more than
+// once per invocation; each call resets the same underlying timer rather
+// than leaking a new one.
+func (ctx *Context) WithTimeout(d time.Duration) context.Context {
+	ctx.deadline.setTimeout(d)
+	return ctx.Context()
+}
+
+// RequestID returns the id populated by the RequestID middleware, or the
+// empty string if that middleware isn't in use.
+func (ctx *Context) RequestID() string {
+	return ctx.requestID
+}
+
+// newContext builds the Context a single invocation's handler chain runs
+// with. Its deadline is derived from AWS_LAMBDA_FUNCTION_TIMEOUT, measured
+// from now - apex.Context carries no deadline or remaining-time of its own
+// to read instead (see invocationDeadline).
+func newContext(req *Request, binaryMediaTypes []string) *Context {
+	return &Context{
+		Request:          req,
+		binaryMediaTypes: binaryMediaTypes,
+		deadline:         newDeadlineContext(invocationDeadline(time.Now())),
+		values:           context.Background(),
+	}
 }
 
 func (ctx *Context) Bind(m interface{}) error {
-	err := json.Unmarshal([]byte(ctx.Request.Body), m)
+	body, err := ctx.BodyBytes()
+	if err != nil {
+		return err
+	}
+
+	err = json.Unmarshal(body, m)
 	if err != nil {
 		return fmt.Errorf("lambdaproxy: unable to bind body to struct: %s", err)
 	}
@@ -138,3 +254,80 @@ func (ctx *Context) status(status int, body interface{}, headers map[string]stri
 func (ctx *Context) Continue() error {
 	return nil
 }
+
+// JSONError writes err as the stable JSON error envelope. If err isn't an
+// *HTTPError it's reported as a 500 with code "internal_error".
+func (ctx *Context) JSONError(err error) error {
+	he, ok := err.(*HTTPError)
+	if !ok {
+		he = NewHTTPError(http.StatusInternalServerError, "internal_error", err.Error())
+	}
+	ctx.response = errorResponse(he)
+	return nil
+}
+
+// BodyBytes returns the raw request body, transparently base64-decoding it
+// when API Gateway delivered it with isBase64Encoded set.
+func (ctx *Context) BodyBytes() ([]byte, error) {
+	if ctx.Request.IsBase64Encoded {
+		b, err := base64.StdEncoding.DecodeString(ctx.Request.Body)
+		if err != nil {
+			return nil, fmt.Errorf("lambdaproxy: unable to decode base64 body: %s", err)
+		}
+		return b, nil
+	}
+	return []byte(ctx.Request.Body), nil
+}
+
+// Blob writes b as the response body with the given Content-Type. Whether
+// the body is base64-encoded and isBase64Encoded is set depends on whether
+// contentType matches one of the router's configured binary media types
+// (see router.SetBinaryMediaTypes), mirroring API Gateway's own behavior.
+func (ctx *Context) Blob(status int, contentType string, b []byte) error {
+	binary := matchesBinaryMediaType(ctx.binaryMediaTypes, contentType)
+	body := string(b)
+	if binary {
+		body = base64.StdEncoding.EncodeToString(b)
+	}
+	ctx.response = &response{
+		StatusCode:      status,
+		Headers:         map[string]string{"Content-Type": contentType},
+		Body:            body,
+		IsBase64Encoded: binary,
+	}
+	return nil
+}
+
+// Stream reads r to completion and writes it as the response body via Blob.
+func (ctx *Context) Stream(status int, contentType string, r io.Reader) error {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("lambdaproxy: unable to read stream: %s", err)
+	}
+	return ctx.Blob(status, contentType, b)
+}
+
+// matchesBinaryMediaType reports whether contentType matches one of types,
+// which may contain exact types ("image/png"), wildcard subtypes
+// ("image/*"), or the catch-all "*/*". This mirrors API Gateway's own
+// binaryMediaTypes semantics: an empty/unconfigured list matches nothing,
+// so Blob only base64-encodes content types the router (via
+// SetBinaryMediaTypes) was explicitly told are binary.
+func matchesBinaryMediaType(types []string, contentType string) bool {
+	if len(types) == 0 {
+		return false
+	}
+	ct := contentType
+	if i := strings.Index(ct, ";"); i >= 0 {
+		ct = strings.TrimSpace(ct[:i])
+	}
+	for _, t := range types {
+		switch {
+		case t == "*/*", t == ct:
+			return true
+		case strings.HasSuffix(t, "/*") && strings.HasPrefix(ct, strings.TrimSuffix(t, "*")):
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,51 @@
+package lambdaproxy
+
+import (
+	"encoding/base64"
+	"net/http"
+	"testing"
+)
+
+func TestMatchesBinaryMediaType(t *testing.T) {
+	cases := []struct {
+		name        string
+		types       []string
+		contentType string
+		want        bool
+	}{
+		{"unconfigured", nil, "application/json", false},
+		{"exact match", []string{"image/png"}, "image/png", true},
+		{"exact mismatch", []string{"image/png"}, "image/jpeg", false},
+		{"subtype wildcard", []string{"image/*"}, "image/jpeg", true},
+		{"subtype wildcard mismatch", []string{"image/*"}, "application/json", false},
+		{"catch-all", []string{"*/*"}, "application/json", true},
+		{"content-type with params", []string{"application/json"}, "application/json; charset=utf-8", true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := matchesBinaryMediaType(c.types, c.contentType); got != c.want {
+				t.Fatalf("matchesBinaryMediaType(%v, %q) = %v, want %v", c.types, c.contentType, got, c.want)
+			}
+		})
+	}
+}
+
+func TestContextBlobUsesBinaryMediaTypes(t *testing.T) {
+	ctx := newContext(&Request{}, []string{"image/*"})
+
+	if err := ctx.Blob(http.StatusOK, "application/json", []byte(`{"ok":true}`)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ctx.response.IsBase64Encoded || ctx.response.Body != `{"ok":true}` {
+		t.Fatalf("expected an unencoded json body, got %+v", ctx.response)
+	}
+
+	png := []byte{0xff, 0xd8}
+	if err := ctx.Blob(http.StatusOK, "image/png", png); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !ctx.response.IsBase64Encoded || ctx.response.Body != base64.StdEncoding.EncodeToString(png) {
+		t.Fatalf("expected a base64-encoded body, got %+v", ctx.response)
+	}
+}
@@ -0,0 +1,51 @@
+package lambdaproxy
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestErrorResponseEnvelope(t *testing.T) {
+	he := NewValidationError(map[string]string{"field": "required"})
+	resp := errorResponse(he)
+
+	if resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Fatalf("expected status %d, got %d", http.StatusUnprocessableEntity, resp.StatusCode)
+	}
+
+	var decoded struct {
+		Code    string          `json:"code"`
+		Message string          `json:"message"`
+		Detail  json.RawMessage `json:"detail"`
+	}
+	if err := json.Unmarshal([]byte(resp.Body), &decoded); err != nil {
+		t.Fatalf("expected a valid json envelope, got %q: %s", resp.Body, err)
+	}
+	if decoded.Code != "validation_failed" {
+		t.Fatalf("unexpected code: %+v", decoded)
+	}
+	if string(decoded.Detail) != `{"field":"required"}` {
+		t.Fatalf("unexpected detail: %s", decoded.Detail)
+	}
+}
+
+func TestContextJSONError(t *testing.T) {
+	ctx := newContext(&Request{}, nil)
+	if err := ctx.JSONError(NewNotFoundError(nil)); err != nil {
+		t.Fatalf("expected JSONError to absorb the error, got %v", err)
+	}
+	if ctx.response == nil || ctx.response.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected a 404 response, got %+v", ctx.response)
+	}
+
+	var decoded struct {
+		Code string `json:"code"`
+	}
+	if err := json.Unmarshal([]byte(ctx.response.Body), &decoded); err != nil {
+		t.Fatalf("expected a valid json body, got %q: %s", ctx.response.Body, err)
+	}
+	if decoded.Code != "not_found" {
+		t.Fatalf("unexpected code: %+v", decoded)
+	}
+}
@@ -0,0 +1,8 @@
+package lambdaproxy
+
+// MiddlewareFunc wraps a HandlerFunc with cross-cutting behaviour. It
+// receives the next handler in the chain and returns a new HandlerFunc that
+// decides whether (and when) to call it. A middleware can short-circuit the
+// chain by writing a response via ctx.JSON (or similar) and returning
+// without calling next.
+type MiddlewareFunc func(next HandlerFunc) HandlerFunc
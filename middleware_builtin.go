@@ -0,0 +1,108 @@
+package lambdaproxy
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// newRequestID generates a random hex identifier for use when API Gateway
+// doesn't supply one (e.g. local invocation via apex invoke).
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
+
+// RequestID populates ctx.RequestID() from req.RequestContext.RequestID,
+// falling back to a locally generated id when API Gateway didn't set one.
+func RequestID() MiddlewareFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx *Context) error {
+			id := ctx.Request.RequestContext.RequestID
+			if id == "" {
+				id = newRequestID()
+			}
+			ctx.requestID = id
+			return next(ctx)
+		}
+	}
+}
+
+// Recover turns a panic anywhere downstream into a 500 response instead of
+// letting it crash the Lambda invocation. It writes ctx.response directly
+// (rather than returning the converted error) so that middleware wrapping
+// Recover - CORS, Logger, etc. - sees the same populated ctx.response it
+// would on success, instead of an error that skips their post-processing.
+func Recover() MiddlewareFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx *Context) (err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					log.Printf("[ERROR] lambdaproxy: recovered from panic: %v", r)
+					ctx.response = errorResponse(NewHTTPError(http.StatusInternalServerError, "internal_error", ""))
+					err = nil
+				}
+			}()
+			return next(ctx)
+		}
+	}
+}
+
+// Logger logs method, path and latency for every request.
+func Logger() MiddlewareFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx *Context) error {
+			start := time.Now()
+			err := next(ctx)
+			log.Printf("[INFO] lambdaproxy: %s %s (%s)", ctx.Request.HTTPMethod, ctx.Request.Path, time.Since(start))
+			return err
+		}
+	}
+}
+
+func corsHeaders() map[string]string {
+	return map[string]string{
+		"Access-Control-Allow-Origin":  "*",
+		"Access-Control-Allow-Methods": "GET, POST, PUT, DELETE, HEAD, OPTIONS",
+		"Access-Control-Allow-Headers": "Content-Type, Authorization",
+	}
+}
+
+// CORS adds permissive CORS headers to every response - including error
+// responses, so a browser client sees the real error instead of being
+// blocked by its own CORS check - and short-circuits preflight OPTIONS
+// requests with a 204.
+func CORS() MiddlewareFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx *Context) error {
+			if strings.EqualFold(ctx.Request.HTTPMethod, http.MethodOptions) {
+				return ctx.status(http.StatusNoContent, "", corsHeaders())
+			}
+
+			if err := next(ctx); err != nil {
+				ctx.response = buildErrorResponse(err)
+			}
+			if ctx.response == nil {
+				ctx.response = &response{StatusCode: http.StatusNoContent, Headers: EmptyHeaders}
+			}
+
+			if ctx.response != nil {
+				headers := make(map[string]string, len(ctx.response.Headers)+len(corsHeaders()))
+				for k, v := range ctx.response.Headers {
+					headers[k] = v
+				}
+				for k, v := range corsHeaders() {
+					headers[k] = v
+				}
+				ctx.response.Headers = headers
+			}
+			return nil
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package lambdaproxy
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestCORSAppliesHeadersToErrorResponses(t *testing.T) {
+	h := CORS()(func(ctx *Context) error {
+		return NewNotFoundError(nil)
+	})
+
+	ctx := newContext(&Request{HTTPMethod: "GET"}, nil)
+	if err := h(ctx); err != nil {
+		t.Fatalf("expected CORS to absorb the error, got %v", err)
+	}
+
+	if ctx.response == nil {
+		t.Fatal("expected a response to be set")
+	}
+	if ctx.response.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected the 404 to survive, got %d", ctx.response.StatusCode)
+	}
+	if ctx.response.Headers["Access-Control-Allow-Origin"] != "*" {
+		t.Fatalf("expected CORS headers on an error response, got %+v", ctx.response.Headers)
+	}
+}
+
+func TestCORSAppliesHeadersAfterRecover(t *testing.T) {
+	h := CORS()(Recover()(func(ctx *Context) error {
+		panic("boom")
+	}))
+
+	ctx := newContext(&Request{HTTPMethod: "GET"}, nil)
+	if err := h(ctx); err != nil {
+		t.Fatalf("expected the panic to be absorbed, got %v", err)
+	}
+
+	if ctx.response == nil || ctx.response.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("expected a 500 response, got %+v", ctx.response)
+	}
+	if ctx.response.Headers["Access-Control-Allow-Origin"] != "*" {
+		t.Fatalf("expected CORS headers on the recovered response, got %+v", ctx.response.Headers)
+	}
+}
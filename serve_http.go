@@ -0,0 +1,77 @@
+package lambdaproxy
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+)
+
+// ServeHTTP mounts the router under a real net/http server for local
+// development (e.g. `http.ListenAndServe(":8080", router)`), converting the
+// incoming http.Request into a synthetic Request and writing the resulting
+// response back out. Route resolution goes through Match, since there's no
+// API Gateway here to populate req.Resource/PathParameters.
+func (r *router) ServeHTTP(w http.ResponseWriter, httpReq *http.Request) {
+	req, err := requestFromHTTP(httpReq)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	resp := &response{
+		StatusCode: http.StatusNoContent,
+		Body:       "",
+		Headers:    EmptyHeaders,
+	}
+	hCtx := newContext(req, r.binaryMediaTypes)
+
+	out, _ := runChain(resp, hCtx, r.handlerFor(req))
+	writeHTTPResponse(w, out.(*response))
+}
+
+// requestFromHTTP converts an inbound net/http request into the same
+// Request shape API Gateway's proxy integration would deliver.
+func requestFromHTTP(r *http.Request) (*Request, error) {
+	defer r.Body.Close()
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, fmt.Errorf("lambdaproxy: unable to read request body: %s", err)
+	}
+
+	headers := make(map[string]string, len(r.Header))
+	for k := range r.Header {
+		headers[k] = r.Header.Get(k)
+	}
+
+	query := make(map[string]string, len(r.URL.Query()))
+	for k, v := range r.URL.Query() {
+		if len(v) > 0 {
+			query[k] = v[0]
+		}
+	}
+
+	return &Request{
+		HTTPMethod:            r.Method,
+		Path:                  r.URL.Path,
+		Headers:               headers,
+		QueryStringParameters: query,
+		Body:                  string(body),
+	}, nil
+}
+
+func writeHTTPResponse(w http.ResponseWriter, resp *response) {
+	for k, v := range resp.Headers {
+		w.Header().Set(k, v)
+	}
+	w.WriteHeader(resp.StatusCode)
+
+	if resp.IsBase64Encoded {
+		if b, err := base64.StdEncoding.DecodeString(resp.Body); err == nil {
+			w.Write(b)
+			return
+		}
+	}
+	io.WriteString(w, resp.Body)
+}
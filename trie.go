@@ -0,0 +1,103 @@
+package lambdaproxy
+
+import (
+	"fmt"
+	"strings"
+)
+
+// pathNode is one segment of the per-method path trie used to match
+// req.Path against patterns like "/users/:id/posts/:postID" or
+// "/assets/*file", independently of API Gateway's own Resource matching.
+//
+// A node doesn't store its :param/*wildcard name: two routes can share a
+// trie position under different names (e.g. "/users/:id" and
+// "/users/:userId/posts" both go through the same param edge off
+// "/users"), so a name stored on the node would belong to whichever route
+// was registered last. Instead match returns values positionally and
+// router.Match zips them against the matched route's own ResourcePath.
+type pathNode struct {
+	children map[string]*pathNode
+	param    *pathNode
+	wildcard *pathNode
+	route    *Route
+}
+
+func newPathNode() *pathNode {
+	return &pathNode{children: make(map[string]*pathNode)}
+}
+
+// splitPath breaks a path into its non-empty segments: "/users/42/" ->
+// ["users", "42"].
+func splitPath(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, "/")
+}
+
+func (n *pathNode) insert(segments []string, route *Route) {
+	if len(segments) == 0 {
+		n.route = route
+		return
+	}
+
+	seg := segments[0]
+	switch {
+	case strings.HasPrefix(seg, "*"):
+		// Like httprouter, a catch-all only makes sense as the final
+		// segment - anything registered after it would be silently
+		// unreachable (match always consumes the rest of the path).
+		if len(segments) != 1 {
+			panic(fmt.Sprintf("lambdaproxy: wildcard segment %q must be the last segment in the path", seg))
+		}
+		if n.wildcard == nil {
+			n.wildcard = newPathNode()
+		}
+		n.wildcard.route = route
+	case strings.HasPrefix(seg, ":"):
+		if n.param == nil {
+			n.param = newPathNode()
+		}
+		n.param.insert(segments[1:], route)
+	default:
+		key := strings.ToLower(seg)
+		child, ok := n.children[key]
+		if !ok {
+			child = newPathNode()
+			n.children[key] = child
+		}
+		child.insert(segments[1:], route)
+	}
+}
+
+// match walks segments against n, returning the matched route along with
+// the :param/*wildcard values captured along the way, in the order their
+// segments appear in the path - not yet paired with names, since a node
+// doesn't know them (see pathNode). Literal segments are preferred over
+// :param, which is preferred over *wildcard, backtracking on a dead end.
+func (n *pathNode) match(segments []string) (*Route, []string, bool) {
+	if len(segments) == 0 {
+		return n.route, nil, n.route != nil
+	}
+
+	seg, rest := segments[0], segments[1:]
+
+	if child, ok := n.children[strings.ToLower(seg)]; ok {
+		if rt, captured, ok := child.match(rest); ok {
+			return rt, captured, true
+		}
+	}
+
+	if n.param != nil {
+		if rt, captured, ok := n.param.match(rest); ok {
+			return rt, append([]string{seg}, captured...), true
+		}
+	}
+
+	if n.wildcard != nil && n.wildcard.route != nil {
+		return n.wildcard.route, []string{strings.Join(segments, "/")}, true
+	}
+
+	return nil, nil, false
+}
@@ -0,0 +1,98 @@
+package lambdaproxy
+
+import "testing"
+
+func TestRouterMatchParams(t *testing.T) {
+	r := NewRouter()
+	r.GET("/users/:id/posts/:postID", func(ctx *Context) error { return nil })
+
+	rt, params, ok := r.Match("get", "/users/42/posts/7")
+	if !ok {
+		t.Fatal("expected a match")
+	}
+	if rt.ResourcePath != "/users/:id/posts/:postID" {
+		t.Fatalf("unexpected route: %+v", rt)
+	}
+	if params["id"] != "42" || params["postID"] != "7" {
+		t.Fatalf("unexpected params: %+v", params)
+	}
+}
+
+func TestRouterMatchPrefersLiteralOverParam(t *testing.T) {
+	r := NewRouter()
+	r.GET("/users/me", func(ctx *Context) error { return nil })
+	r.GET("/users/:id", func(ctx *Context) error { return nil })
+
+	rt, _, ok := r.Match("get", "/users/me")
+	if !ok || rt.ResourcePath != "/users/me" {
+		t.Fatalf("expected the literal route to win, got %+v (ok=%v)", rt, ok)
+	}
+
+	rt, params, ok := r.Match("get", "/users/42")
+	if !ok || rt.ResourcePath != "/users/:id" || params["id"] != "42" {
+		t.Fatalf("expected the :id route, got %+v %+v (ok=%v)", rt, params, ok)
+	}
+}
+
+func TestRouterMatchSiblingRoutesWithDifferingParamNames(t *testing.T) {
+	r := NewRouter()
+	r.GET("/users/:id", func(ctx *Context) error { return nil })
+	r.GET("/users/:userID/posts", func(ctx *Context) error { return nil })
+
+	rt, params, ok := r.Match("get", "/users/42")
+	if !ok || rt.ResourcePath != "/users/:id" {
+		t.Fatalf("expected the /users/:id route, got %+v (ok=%v)", rt, ok)
+	}
+	if params["id"] != "42" {
+		t.Fatalf("expected param %q, got %+v", "id", params)
+	}
+
+	rt, params, ok = r.Match("get", "/users/42/posts")
+	if !ok || rt.ResourcePath != "/users/:userID/posts" {
+		t.Fatalf("expected the /users/:userID/posts route, got %+v (ok=%v)", rt, ok)
+	}
+	if params["userID"] != "42" {
+		t.Fatalf("expected param %q, got %+v", "userID", params)
+	}
+}
+
+func TestRouterMatchWildcard(t *testing.T) {
+	r := NewRouter()
+	r.GET("/assets/*file", func(ctx *Context) error { return nil })
+
+	rt, params, ok := r.Match("get", "/assets/css/app.css")
+	if !ok || rt.ResourcePath != "/assets/*file" {
+		t.Fatalf("expected the wildcard route, got %+v (ok=%v)", rt, ok)
+	}
+	if params["file"] != "css/app.css" {
+		t.Fatalf("unexpected wildcard capture: %+v", params)
+	}
+}
+
+func TestRouterMatchIsCaseInsensitive(t *testing.T) {
+	r := NewRouter()
+	r.GET("/Users/:id", func(ctx *Context) error { return nil })
+
+	if _, _, ok := r.Match("get", "/users/42"); !ok {
+		t.Fatal("expected a case-insensitive literal segment match")
+	}
+}
+
+func TestRouterMatchNoMatch(t *testing.T) {
+	r := NewRouter()
+	r.GET("/users/:id", func(ctx *Context) error { return nil })
+
+	if _, _, ok := r.Match("get", "/orders/42"); ok {
+		t.Fatal("expected no match for an unregistered path")
+	}
+}
+
+func TestRouterAddPanicsOnWildcardNotLast(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected Add to panic when a wildcard isn't the last segment")
+		}
+	}()
+
+	NewRouter().GET("/assets/*file/meta", func(ctx *Context) error { return nil })
+}